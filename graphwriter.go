@@ -0,0 +1,342 @@
+//
+// graphwriter.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// GraphWriter emits a set of RFCs and the edges between them in
+// some graph interchange format. Implementations are selected with
+// NewGraphWriter based on the -format flag.
+type GraphWriter interface {
+	Write(w io.Writer, nodes map[string]*RFC, edges []Edge) error
+}
+
+// NewGraphWriter returns the GraphWriter for the named format: dot,
+// graphml, cytoscape or gexf. The dot writer additionally prints a
+// year timeline and status legend when timeline is true.
+func NewGraphWriter(format string, timeline bool) (GraphWriter, error) {
+	switch format {
+	case "dot", "":
+		return dotWriter{Timeline: timeline}, nil
+	case "graphml":
+		return graphMLWriter{}, nil
+	case "cytoscape":
+		return cytoscapeWriter{}, nil
+	case "gexf":
+		return gexfWriter{}, nil
+	}
+	return nil, fmt.Errorf("unknown graph format %q", format)
+}
+
+func sortedNodes(nodes map[string]*RFC) []*RFC {
+	var result []*RFC
+	for _, rfc := range nodes {
+		result = append(result, rfc)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+	return result
+}
+
+func sortedEdges(edges []Edge) []Edge {
+	result := make([]Edge, len(edges))
+	copy(result, edges)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+func nodeYear(rfc *RFC) int {
+	year, err := rfc.Year()
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+// dotWriter emits Graphviz DOT. When Timeline is set, it also prints
+// a year timeline and a status legend, same-rank-grouped by
+// year, as the original -h flag did.
+type dotWriter struct {
+	Timeline bool
+}
+
+func (d dotWriter) Write(w io.Writer, nodes map[string]*RFC, edges []Edge) error {
+	fmt.Fprintf(w, "digraph rfc {\n")
+
+	var byTypeStatus [Obsoleted + 1][BestCurrentPractice + 1][]*RFC
+	var from, to int
+	ranks := make(map[int][]*RFC)
+
+	for _, rfc := range sortedNodes(nodes) {
+		byTypeStatus[rfc.Type][rfc.Status] = append(byTypeStatus[rfc.Type][rfc.Status], rfc)
+		if !d.Timeline {
+			continue
+		}
+		year := nodeYear(rfc)
+		if from == 0 || year < from {
+			from = year
+		}
+		if to == 0 || year > to {
+			to = year
+		}
+		ranks[year] = append(ranks[year], rfc)
+	}
+
+	if d.Timeline {
+		fmt.Fprintf(w, "// %d-%d\n", from, to)
+		fmt.Fprintf(w, "\tnode [shape=plaintext];\n\t%d", from)
+		for i := from + 1; i <= to; i++ {
+			fmt.Fprintf(w, " -> %d", i)
+		}
+		fmt.Fprintf(w, ";\n\t%d -> Legend [style=invis];\n", to)
+	}
+
+	for t, byStatus := range byTypeStatus {
+		for status, rfcs := range byStatus {
+			if len(rfcs) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\tnode [%s %s]\n", Status(status).Node(), Type(t).Node())
+			for _, rfc := range rfcs {
+				fmt.Fprintf(w, "\t%s;\n", rfc.Number)
+			}
+		}
+	}
+
+	if d.Timeline {
+		for i := from; i <= to; i++ {
+			arr, ok := ranks[i]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "\t{rank=same %d", i)
+			for _, rfc := range arr {
+				fmt.Fprintf(w, " %s", rfc.Number)
+			}
+			fmt.Fprintf(w, "}\n")
+		}
+
+		for status := range statusName {
+			fmt.Fprintf(w, "\tnode [%s style=solid];\n", status.Node())
+			fmt.Fprintf(w, "\t%s;\n", status)
+		}
+		fmt.Fprintf(w, "\t{rank=same Legend")
+		for status := range statusName {
+			fmt.Fprintf(w, " %s", status)
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+
+	for _, edge := range sortedEdges(edges) {
+		fmt.Fprintf(w, "\t%s -> %s%s;\n", edge.From, edge.To, edge.Type.Edge())
+	}
+	fmt.Fprintf(w, "}\n")
+	return nil
+}
+
+// graphMLWriter emits GraphML, encoding Status, Type, Year, Title
+// and Authors as typed node attributes and the edge Type as an edge
+// attribute, so the graph can be loaded into Gephi or yEd without a
+// lossy DOT conversion.
+type graphMLWriter struct{}
+
+func (graphMLWriter) Write(w io.Writer, nodes map[string]*RFC, edges []Edge) error {
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	fmt.Fprintf(w, "\t<key id=\"status\" for=\"node\" attr.name=\"status\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t<key id=\"type\" for=\"node\" attr.name=\"type\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t<key id=\"year\" for=\"node\" attr.name=\"year\" attr.type=\"int\"/>\n")
+	fmt.Fprintf(w, "\t<key id=\"title\" for=\"node\" attr.name=\"title\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t<key id=\"authors\" for=\"node\" attr.name=\"authors\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t<key id=\"reltype\" for=\"edge\" attr.name=\"type\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t<graph id=\"rfc\" edgedefault=\"directed\">\n")
+
+	for _, rfc := range sortedNodes(nodes) {
+		fmt.Fprintf(w, "\t\t<node id=\"%s\">\n", rfc.Number)
+		fmt.Fprintf(w, "\t\t\t<data key=\"status\">%s</data>\n", xmlEscape(rfc.Status.String()))
+		fmt.Fprintf(w, "\t\t\t<data key=\"type\">%s</data>\n", xmlEscape(relTypeName(rfc.Type)))
+		fmt.Fprintf(w, "\t\t\t<data key=\"year\">%d</data>\n", nodeYear(rfc))
+		fmt.Fprintf(w, "\t\t\t<data key=\"title\">%s</data>\n", xmlEscape(rfc.Title))
+		fmt.Fprintf(w, "\t\t\t<data key=\"authors\">%s</data>\n", xmlEscape(rfc.Authors))
+		fmt.Fprintf(w, "\t\t</node>\n")
+	}
+	for i, edge := range sortedEdges(edges) {
+		fmt.Fprintf(w, "\t\t<edge id=\"e%d\" source=\"%s\" target=\"%s\">\n",
+			i, edge.From, edge.To)
+		fmt.Fprintf(w, "\t\t\t<data key=\"reltype\">%s</data>\n", xmlEscape(relTypeName(edge.Type)))
+		fmt.Fprintf(w, "\t\t</edge>\n")
+	}
+
+	fmt.Fprintf(w, "\t</graph>\n")
+	fmt.Fprintf(w, "</graphml>\n")
+	return nil
+}
+
+// gexfWriter emits GEXF 1.3, the Gephi native format, with the same
+// node and edge attributes as graphMLWriter.
+type gexfWriter struct{}
+
+func (gexfWriter) Write(w io.Writer, nodes map[string]*RFC, edges []Edge) error {
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<gexf xmlns=\"http://www.gexf.net/1.3\" version=\"1.3\">\n")
+	fmt.Fprintf(w, "\t<graph mode=\"static\" defaultedgetype=\"directed\">\n")
+	fmt.Fprintf(w, "\t\t<attributes class=\"node\">\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"0\" title=\"status\" type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"1\" title=\"type\" type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"2\" title=\"year\" type=\"integer\"/>\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"3\" title=\"title\" type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"4\" title=\"authors\" type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t\t</attributes>\n")
+	fmt.Fprintf(w, "\t\t<attributes class=\"edge\">\n")
+	fmt.Fprintf(w, "\t\t\t<attribute id=\"0\" title=\"type\" type=\"string\"/>\n")
+	fmt.Fprintf(w, "\t\t</attributes>\n")
+
+	fmt.Fprintf(w, "\t\t<nodes>\n")
+	for _, rfc := range sortedNodes(nodes) {
+		fmt.Fprintf(w, "\t\t\t<node id=\"%s\" label=\"%s\">\n",
+			rfc.Number, xmlEscape(rfc.Title))
+		fmt.Fprintf(w, "\t\t\t\t<attvalues>\n")
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"0\" value=\"%s\"/>\n", xmlEscape(rfc.Status.String()))
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"1\" value=\"%s\"/>\n", xmlEscape(relTypeName(rfc.Type)))
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"2\" value=\"%d\"/>\n", nodeYear(rfc))
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"3\" value=\"%s\"/>\n", xmlEscape(rfc.Title))
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"4\" value=\"%s\"/>\n", xmlEscape(rfc.Authors))
+		fmt.Fprintf(w, "\t\t\t\t</attvalues>\n")
+		fmt.Fprintf(w, "\t\t\t</node>\n")
+	}
+	fmt.Fprintf(w, "\t\t</nodes>\n")
+
+	fmt.Fprintf(w, "\t\t<edges>\n")
+	for i, edge := range sortedEdges(edges) {
+		fmt.Fprintf(w, "\t\t\t<edge id=\"%d\" source=\"%s\" target=\"%s\">\n",
+			i, edge.From, edge.To)
+		fmt.Fprintf(w, "\t\t\t\t<attvalues>\n")
+		fmt.Fprintf(w, "\t\t\t\t\t<attvalue for=\"0\" value=\"%s\"/>\n", xmlEscape(relTypeName(edge.Type)))
+		fmt.Fprintf(w, "\t\t\t\t</attvalues>\n")
+		fmt.Fprintf(w, "\t\t\t</edge>\n")
+	}
+	fmt.Fprintf(w, "\t\t</edges>\n")
+
+	fmt.Fprintf(w, "\t</graph>\n")
+	fmt.Fprintf(w, "</gexf>\n")
+	return nil
+}
+
+// cytoscapeNode and cytoscapeEdge mirror the Cytoscape.js JSON
+// graph elements format ({data: {...}}).
+type cytoscapeNode struct {
+	Data cytoscapeNodeData `json:"data"`
+}
+
+type cytoscapeNodeData struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Type    string `json:"type"`
+	Year    int    `json:"year"`
+	Title   string `json:"title"`
+	Authors string `json:"authors"`
+}
+
+type cytoscapeEdge struct {
+	Data cytoscapeEdgeData `json:"data"`
+}
+
+type cytoscapeEdgeData struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeNode `json:"nodes"`
+	Edges []cytoscapeEdge `json:"edges"`
+}
+
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+}
+
+// cytoscapeWriter emits the Cytoscape.js JSON graph elements
+// format.
+type cytoscapeWriter struct{}
+
+func (cytoscapeWriter) Write(w io.Writer, nodes map[string]*RFC, edges []Edge) error {
+	var g cytoscapeGraph
+	for _, rfc := range sortedNodes(nodes) {
+		g.Elements.Nodes = append(g.Elements.Nodes, cytoscapeNode{
+			Data: cytoscapeNodeData{
+				ID:      rfc.Number,
+				Status:  rfc.Status.String(),
+				Type:    relTypeName(rfc.Type),
+				Year:    nodeYear(rfc),
+				Title:   rfc.Title,
+				Authors: rfc.Authors,
+			},
+		})
+	}
+	for i, edge := range sortedEdges(edges) {
+		g.Elements.Edges = append(g.Elements.Edges, cytoscapeEdge{
+			Data: cytoscapeEdgeData{
+				ID:     fmt.Sprintf("e%d", i),
+				Source: edge.From,
+				Target: edge.To,
+				Type:   relTypeName(edge.Type),
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// relTypeName names a Type for edge/node attribute output (distinct
+// from Type.String, which this package does not otherwise define).
+func relTypeName(t Type) string {
+	switch t {
+	case Current:
+		return "Current"
+	case Updated:
+		return "Updated"
+	case Obsoleted:
+		return "Obsoleted"
+	}
+	return fmt.Sprintf("Type%d", t)
+}
+
+func xmlEscape(s string) string {
+	var out []byte
+	for _, r := range s {
+		switch r {
+		case '&':
+			out = append(out, []byte("&amp;")...)
+		case '<':
+			out = append(out, []byte("&lt;")...)
+		case '>':
+			out = append(out, []byte("&gt;")...)
+		case '"':
+			out = append(out, []byte("&quot;")...)
+		default:
+			out = append(out, []byte(string(r))...)
+		}
+	}
+	return string(out)
+}
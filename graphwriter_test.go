@@ -0,0 +1,154 @@
+//
+// graphwriter_test.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func testGraph() (map[string]*RFC, []Edge) {
+	rfc1 := &RFC{
+		Number:  "1000",
+		Title:   "Example Protocol",
+		Authors: "A. Author",
+		Date:    "January 2000",
+		Status:  ProposedStandard,
+	}
+	rfc2 := &RFC{
+		Number:  "2000",
+		Title:   "Example Protocol v2",
+		Authors: "B. Author",
+		Date:    "January 2005",
+		Status:  InternetStandard,
+		Type:    Obsoleted,
+	}
+	nodes := map[string]*RFC{
+		"1000": rfc1,
+		"2000": rfc2,
+	}
+	edges := []Edge{
+		{From: "2000", To: "1000", Type: Obsoleted},
+	}
+	return nodes, edges
+}
+
+func TestGraphWriters(t *testing.T) {
+	nodes, edges := testGraph()
+
+	for _, format := range []string{"dot", "graphml", "cytoscape", "gexf"} {
+		writer, err := NewGraphWriter(format, false)
+		if err != nil {
+			t.Fatalf("NewGraphWriter(%q): %v", format, err)
+		}
+		var buf bytes.Buffer
+		if err := writer.Write(&buf, nodes, edges); err != nil {
+			t.Fatalf("%s: Write: %v", format, err)
+		}
+		if buf.Len() == 0 {
+			t.Fatalf("%s: empty output", format)
+		}
+	}
+}
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	nodes, edges := testGraph()
+
+	var buf bytes.Buffer
+	writer, err := NewGraphWriter("graphml", false)
+	if err != nil {
+		t.Fatalf("NewGraphWriter: %v", err)
+	}
+	if err := writer.Write(&buf, nodes, edges); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"graphml"`
+		Graph   struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(doc.Graph.Nodes) != len(nodes) {
+		t.Errorf("got %d nodes, want %d", len(doc.Graph.Nodes), len(nodes))
+	}
+	if len(doc.Graph.Edges) != len(edges) {
+		t.Errorf("got %d edges, want %d", len(doc.Graph.Edges), len(edges))
+	}
+}
+
+func TestGEXFRoundTrip(t *testing.T) {
+	nodes, edges := testGraph()
+
+	var buf bytes.Buffer
+	writer, err := NewGraphWriter("gexf", false)
+	if err != nil {
+		t.Fatalf("NewGraphWriter: %v", err)
+	}
+	if err := writer.Write(&buf, nodes, edges); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"gexf"`
+		Graph   struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"nodes>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edges>edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(doc.Graph.Nodes) != len(nodes) {
+		t.Errorf("got %d nodes, want %d", len(doc.Graph.Nodes), len(nodes))
+	}
+	if len(doc.Graph.Edges) != len(edges) {
+		t.Errorf("got %d edges, want %d", len(doc.Graph.Edges), len(edges))
+	}
+}
+
+func TestCytoscapeRoundTrip(t *testing.T) {
+	nodes, edges := testGraph()
+
+	var buf bytes.Buffer
+	writer, err := NewGraphWriter("cytoscape", false)
+	if err != nil {
+		t.Fatalf("NewGraphWriter: %v", err)
+	}
+	if err := writer.Write(&buf, nodes, edges); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var doc cytoscapeGraph
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(doc.Elements.Nodes) != len(nodes) {
+		t.Errorf("got %d nodes, want %d", len(doc.Elements.Nodes), len(nodes))
+	}
+	if len(doc.Elements.Edges) != len(edges) {
+		t.Errorf("got %d edges, want %d", len(doc.Elements.Edges), len(edges))
+	}
+}
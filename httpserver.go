@@ -0,0 +1,234 @@
+//
+// httpserver.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+//go:embed web/*.html
+var webFS embed.FS
+
+var webTemplates = template.Must(template.ParseFS(webFS, "web/*.html"))
+
+// httpServer implements the "-http" browsing mode: a single-binary
+// web UI over the Session's RFC set, akin to "pprof -http".
+type httpServer struct {
+	session *Session
+}
+
+// RunHTTPServer serves the interactive RFC graph explorer on addr
+// until the process exits or ListenAndServe fails.
+func RunHTTPServer(addr string, session *Session) error {
+	s := &httpServer{session: session}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/rfc/", s.handleRFC)
+	mux.HandleFunc("/api/rfc/", s.handleAPIRFC)
+	mux.HandleFunc("/api/graph", s.handleAPIGraph)
+	mux.HandleFunc("/svg/", s.handleSVG)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *httpServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := webTemplates.ExecuteTemplate(w, "index.html", nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *httpServer) rfcFromPath(prefix string, r *http.Request) (*RFC, bool) {
+	number := strings.TrimPrefix(r.URL.Path, prefix)
+	rfc, ok := s.session.all[number]
+	return rfc, ok
+}
+
+func (s *httpServer) handleRFC(w http.ResponseWriter, r *http.Request) {
+	rfc, ok := s.rfcFromPath("/rfc/", r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data := struct {
+		RFC          *RFC
+		ForwardRefs  []neighborRef
+		BackwardRefs []neighborRef
+	}{
+		RFC:          rfc,
+		ForwardRefs:  s.resolveRefs(rfc.Forwards),
+		BackwardRefs: s.resolveRefs(rfc.Backwards),
+	}
+	if err := webTemplates.ExecuteTemplate(w, "rfc.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// neighborRef is a resolved reference to another RFC, used in both
+// the HTML and JSON handlers so clients don't need a second
+// round-trip to get a title and a human-readable relationship type
+// for a linked RFC number.
+type neighborRef struct {
+	Number string `json:"number"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+}
+
+type rfcDump struct {
+	*RFC
+	ForwardRefs  []neighborRef `json:"forwardRefs"`
+	BackwardRefs []neighborRef `json:"backwardRefs"`
+}
+
+func (s *httpServer) resolveRefs(refs map[string]Type) []neighborRef {
+	var result []neighborRef
+	for num, t := range refs {
+		title := num
+		if rfc, ok := s.session.all[num]; ok {
+			title = rfc.Title
+		}
+		result = append(result, neighborRef{
+			Number: num,
+			Title:  title,
+			Type:   relTypeName(t),
+		})
+	}
+	return result
+}
+
+func (s *httpServer) handleAPIRFC(w http.ResponseWriter, r *http.Request) {
+	rfc, ok := s.rfcFromPath("/api/rfc/", r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	dump := rfcDump{
+		RFC:          rfc,
+		ForwardRefs:  s.resolveRefs(rfc.Forwards),
+		BackwardRefs: s.resolveRefs(rfc.Backwards),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dump)
+}
+
+// neighborhood collects the nodes and edges reachable from root
+// within depth hops of Forwards/Backwards references.
+func (s *httpServer) neighborhood(root string, depth int) (map[string]*RFC, []Edge) {
+	nodes := make(map[string]*RFC)
+	edgeSet := make(map[string]Edge)
+
+	frontier := []string{root}
+	if rfc, ok := s.session.all[root]; ok {
+		nodes[root] = rfc
+	}
+
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []string
+		for _, id := range frontier {
+			rfc, ok := s.session.all[id]
+			if !ok {
+				continue
+			}
+			for ref, t := range rfc.Forwards {
+				edge := Edge{From: id, To: ref, Type: t}
+				edgeSet[edge.ID()] = edge
+				if _, ok := nodes[ref]; !ok {
+					if n, ok := s.session.all[ref]; ok {
+						nodes[ref] = n
+						next = append(next, ref)
+					}
+				}
+			}
+			for ref, t := range rfc.Backwards {
+				edge := Edge{From: ref, To: id, Type: t}
+				edgeSet[edge.ID()] = edge
+				if _, ok := nodes[ref]; !ok {
+					if n, ok := s.session.all[ref]; ok {
+						nodes[ref] = n
+						next = append(next, ref)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var edges []Edge
+	for _, e := range edgeSet {
+		edges = append(edges, e)
+	}
+	return nodes, edges
+}
+
+func parseDepth(r *http.Request) int {
+	depth := 1
+	if v := r.URL.Query().Get("depth"); len(v) > 0 {
+		if d, err := strconv.Atoi(v); err == nil && d >= 0 {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func (s *httpServer) handleAPIGraph(w http.ResponseWriter, r *http.Request) {
+	root := r.URL.Query().Get("root")
+	if _, ok := s.session.all[root]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	depth := parseDepth(r)
+	nodes, edges := s.neighborhood(root, depth)
+
+	writer := cytoscapeWriter{}
+	w.Header().Set("Content-Type", "application/json")
+	if err := writer.Write(w, nodes, edges); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *httpServer) handleSVG(w http.ResponseWriter, r *http.Request) {
+	root := strings.TrimPrefix(r.URL.Path, "/svg/")
+	if _, ok := s.session.all[root]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	depth := parseDepth(r)
+	nodes, edges := s.neighborhood(root, depth)
+
+	var dot bytes.Buffer
+	if err := (dotWriter{}).Write(&dot, nodes, edges); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cmd := exec.Command("dot", "-Tsvg")
+	cmd.Stdin = &dot
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		http.Error(w, fmt.Sprintf("rendering SVG requires graphviz's dot: %s", err),
+			http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(out.Bytes())
+}
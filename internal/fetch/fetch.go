@@ -0,0 +1,175 @@
+//
+// fetch.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+// Package fetch implements a small HTTP fetcher that downloads a
+// remote file into a local cache directory, revalidating it with
+// conditional requests and retrying transient failures with
+// exponential backoff.
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	cacheDirName = "markkurossi-rfc"
+
+	backoffBase = time.Second
+	backoffCap  = 30 * time.Second
+	maxAttempts = 5
+)
+
+// meta is the cached conditional-request state for a fetched file,
+// stored next to it as "<file>.meta".
+type meta struct {
+	ETag         string
+	LastModified string
+}
+
+// File fetches the given URL into the local cache directory,
+// returning the path to the cached file. If the server reports that
+// the cached copy is still current (HTTP 304), the cached file is
+// reused as is.
+func File(url string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, filepath.Base(url))
+	m := readMeta(path)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		if len(m.ETag) > 0 {
+			req.Header.Set("If-None-Match", m.ETag)
+		}
+		if len(m.LastModified) > 0 {
+			req.Header.Set("If-Modified-Since", m.LastModified)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			if attempt == maxAttempts-1 {
+				return "", err
+			}
+			sleep(backoff(attempt, 0))
+			continue
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			resp.Body.Close()
+			return path, nil
+
+		case resp.StatusCode == http.StatusOK:
+			err := save(path, resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return "", err
+			}
+			writeMeta(path, meta{
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+			})
+			return path, nil
+
+		case resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= 500:
+			retryAfter := retryAfter(resp.Header.Get("Retry-After"))
+			resp.Body.Close()
+			if attempt == maxAttempts-1 {
+				return "", fmt.Errorf("fetch: %s: %s", url, resp.Status)
+			}
+			sleep(backoff(attempt, retryAfter))
+			continue
+
+		default:
+			resp.Body.Close()
+			return "", fmt.Errorf("fetch: %s: %s", url, resp.Status)
+		}
+	}
+
+	return "", fmt.Errorf("fetch: %s: too many attempts", url)
+}
+
+// backoff computes the delay before the next attempt: base*2^n,
+// capped at backoffCap, or the server-provided Retry-After if it is
+// longer.
+func backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := backoffBase * time.Duration(1<<uint(attempt))
+	if d > backoffCap {
+		d = backoffCap
+	}
+	if retryAfter > d {
+		d = retryAfter
+	}
+	return d
+}
+
+// sleep is a variable so tests can stub out the actual waiting while
+// still observing the computed backoff durations.
+var sleep = time.Sleep
+
+// retryAfter parses a Retry-After header, which may be either a
+// number of seconds or an HTTP-date. Unparsable or absent headers
+// return 0.
+func retryAfter(header string) time.Duration {
+	if len(header) == 0 {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		d := time.Until(t)
+		if d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func save(path string, r io.Reader) error {
+	tmp := path + fmt.Sprintf(".tmp%d", rand.Int())
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, cacheDirName), nil
+}
@@ -0,0 +1,207 @@
+//
+// fetch_test.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stubSleep replaces the package's sleep variable with one that
+// records the requested durations instead of actually waiting,
+// restores it when the test ends, and returns the recorded durations.
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { sleep = orig })
+	return &slept
+}
+
+func TestFetchRetriesOnTooManyRequestsWithRetryAfter(t *testing.T) {
+	slept := stubSleep(t)
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("index body"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	path, err := File(srv.URL + "/rfc-index.txt")
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("got %d requests, want 2", requests)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 5*time.Second {
+		t.Fatalf("got sleeps %v, want a single 5s sleep honoring Retry-After", *slept)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "index body" {
+		t.Fatalf("got body %q, want %q", data, "index body")
+	}
+}
+
+func TestFetchRetriesOnServerErrorWithoutRetryAfter(t *testing.T) {
+	slept := stubSleep(t)
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("index body"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, err := File(srv.URL + "/rfc-index.txt"); err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("got %d requests, want 2", requests)
+	}
+	// attempt 0 with no Retry-After: base*2^0 = 1s.
+	if len(*slept) != 1 || (*slept)[0] != backoffBase {
+		t.Fatalf("got sleeps %v, want a single %v backoff", *slept, backoffBase)
+	}
+}
+
+func TestFetchReusesCacheOnNotModified(t *testing.T) {
+	stubSleep(t)
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "\"abc123\"" {
+			t.Errorf("got If-None-Match %q, want %q", r.Header.Get("If-None-Match"), `"abc123"`)
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+
+	cacheDir := filepath.Join(dir, cacheDirName)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	url := srv.URL + "/rfc-index.txt"
+	path := filepath.Join(cacheDir, filepath.Base(url))
+	if err := os.WriteFile(path, []byte("cached body"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	writeMeta(path, meta{ETag: `"abc123"`})
+
+	got, err := File(url)
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("got %d requests, want 1", requests)
+	}
+	if got != path {
+		t.Fatalf("got path %q, want %q", got, path)
+	}
+	data, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "cached body" {
+		t.Fatalf("got body %q, want the untouched cached copy", data)
+	}
+}
+
+func TestFetchWritesCacheAndMetaOnOK(t *testing.T) {
+	stubSleep(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"xyz789"`)
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("fresh body"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	path, err := File(srv.URL + "/rfc-index.txt")
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "fresh body" {
+		t.Fatalf("got body %q, want %q", data, "fresh body")
+	}
+
+	m := readMeta(path)
+	if m.ETag != `"xyz789"` {
+		t.Errorf("got ETag %q, want %q", m.ETag, `"xyz789"`)
+	}
+	if m.LastModified != "Wed, 21 Oct 2015 07:28:00 GMT" {
+		t.Errorf("got Last-Modified %q, want %q", m.LastModified, "Wed, 21 Oct 2015 07:28:00 GMT")
+	}
+}
+
+func TestRetryAfterParsesSecondsAndHTTPDate(t *testing.T) {
+	if got := retryAfter(""); got != 0 {
+		t.Errorf("empty header: got %v, want 0", got)
+	}
+	if got := retryAfter("3"); got != 3*time.Second {
+		t.Errorf("seconds header: got %v, want 3s", got)
+	}
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got := retryAfter(future)
+	if got <= 0 || got > 10*time.Second {
+		t.Errorf("HTTP-date header: got %v, want roughly 10s", got)
+	}
+	if got := retryAfter("not-a-valid-header"); got != 0 {
+		t.Errorf("invalid header: got %v, want 0", got)
+	}
+}
+
+func TestBackoffCapsAndHonorsRetryAfter(t *testing.T) {
+	if got := backoff(0, 0); got != backoffBase {
+		t.Errorf("attempt 0: got %v, want %v", got, backoffBase)
+	}
+	if got := backoff(10, 0); got != backoffCap {
+		t.Errorf("attempt 10: got %v, want cap %v", got, backoffCap)
+	}
+	if got := backoff(0, 20*time.Second); got != 20*time.Second {
+		t.Errorf("Retry-After longer than computed backoff: got %v, want 20s", got)
+	}
+}
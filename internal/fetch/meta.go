@@ -0,0 +1,38 @@
+//
+// meta.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package fetch
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func metaPath(path string) string {
+	return path + ".meta"
+}
+
+// readMeta reads the cached conditional-request state for path. A
+// missing or invalid meta file just means an unconditional fetch.
+func readMeta(path string) meta {
+	var m meta
+	data, err := os.ReadFile(metaPath(path))
+	if err != nil {
+		return m
+	}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+func writeMeta(path string, m meta) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	os.WriteFile(metaPath(path), data, 0644)
+}
@@ -12,11 +12,14 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/markkurossi/rfc/internal/fetch"
 )
 
 var (
@@ -27,10 +30,7 @@ var (
 	reStatus   = regexp.MustCompilePOSIX(`Status:[[:space:]]*(.*)`)
 	reRef      = regexp.MustCompilePOSIX(`RFC([[:digit:]]+)(.*)`)
 
-	RFCs      = make(map[string]*RFC)
-	processed = make(map[string]*RFC)
-	edgeMap   = make(map[string]Edge)
-	graphs    []Graph
+	RFCs = make(map[string]*RFC)
 )
 
 func GetRFC(id string) *RFC {
@@ -213,16 +213,36 @@ type Graph struct {
 }
 
 func main() {
-	index := flag.String("i", "rfc-index.txt", "RFC index file")
+	index := flag.String("i", "rfc-index.txt",
+		"RFC index file, or an https:// URL to fetch and cache")
 	traverse := flag.String("t", "", "RFC number to traverse")
 	size := flag.Int("s", 0, "The minimum graph size")
 	list := flag.Bool("l", false, "List graphs")
 	graph := flag.Bool("g", false, "Print RFC graphs")
 	root := flag.String("r", "", "Graph roots, default to all > size")
 	timeline := flag.Bool("h", false, "Print timeline and legend")
+	format := flag.String("format", "dot",
+		"Graph output format: dot, graphml, cytoscape, gexf")
+	interactive := flag.Bool("interactive", false,
+		"Enter interactive command mode")
+	mineSize := flag.Int("mine", 0,
+		"Mine frequent subgraph patterns of up to this many vertices")
+	minSupport := flag.Int("min-support", 2,
+		"Minimum support for -mine patterns")
+	httpAddr := flag.String("http", "",
+		"Serve an interactive RFC graph explorer on this address, e.g. :6060")
 	flag.Parse()
 
-	file, err := os.Open(*index)
+	path := *index
+	if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
+		cached, err := fetch.File(path)
+		if err != nil {
+			panic(err)
+		}
+		path = cached
+	}
+
+	file, err := os.Open(path)
 	if err != nil {
 		panic(err)
 	}
@@ -252,6 +272,25 @@ func main() {
 		rfc.SetTypes()
 	}
 
+	session := NewSession(RFCs)
+
+	if *interactive {
+		session.Run(os.Stdin, os.Stdout)
+		return
+	}
+
+	if *mineSize > 0 {
+		printMinedPatterns(os.Stdout, mineGraph(RFCs), *mineSize, *minSupport)
+		return
+	}
+
+	if len(*httpAddr) > 0 {
+		if err := RunHTTPServer(*httpAddr, session); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	var rfcs []*RFC
 	if len(*root) > 0 {
 		numbers := strings.Split(*root, ",")
@@ -265,23 +304,29 @@ func main() {
 	}
 
 	// Find graphs larger than *size.
-	findGraphs(rfcs, *size)
+	session.findGraphs(rfcs, *size)
 
 	// SSH: 4250
 	// TLS: 4346
 	if len(*traverse) > 0 {
-		printTree(*traverse)
+		session.printTree(os.Stdout, *traverse)
 	}
 	if *list {
-		sort.SliceStable(graphs, func(i, j int) bool {
-			return graphs[i].Leader.Number < graphs[j].Leader.Number
+		sort.SliceStable(session.graphs, func(i, j int) bool {
+			return session.graphs[i].Leader.Number < session.graphs[j].Leader.Number
 		})
-		for _, g := range graphs {
+		for _, g := range session.graphs {
 			fmt.Printf("%s\t%d\t%s\n", g.Leader.Number, g.Size, g.Leader.Title)
 		}
 	}
 	if *graph {
-		printGraph(*timeline)
+		writer, err := NewGraphWriter(*format, *timeline)
+		if err != nil {
+			panic(err)
+		}
+		if err := writer.Write(os.Stdout, session.processedNodes(), session.edges()); err != nil {
+			panic(err)
+		}
 	}
 }
 
@@ -344,140 +389,58 @@ func parseRefs(input string) []string {
 	}
 }
 
-func printTree(root string) {
+func (s *Session) printTree(w io.Writer, root string) {
 	seen := make(map[string]*RFC)
 
-	traverse(root, seen)
+	s.traverse(w, root, seen)
 }
 
-func traverse(id string, seen map[string]*RFC) {
+func (s *Session) traverse(w io.Writer, id string, seen map[string]*RFC) {
+	if s.isIgnored(id) {
+		return
+	}
 	_, ok := seen[id]
 	if ok {
 		return
 	}
-	rfc := GetRFC(id)
+	rfc := s.getRFC(id)
 	seen[id] = rfc
-	fmt.Printf("%s\n", rfc)
+	fmt.Fprintf(w, "%s\n", rfc)
 
-	for r, _ := range rfc.Forwards {
-		traverse(r, seen)
+	for r := range rfc.Forwards {
+		s.traverse(w, r, seen)
 	}
-	for r, _ := range rfc.Backwards {
-		traverse(r, seen)
+	for r := range rfc.Backwards {
+		s.traverse(w, r, seen)
 	}
 }
 
-func findGraphs(rfcs []*RFC, size int) {
+func (s *Session) findGraphs(rfcs []*RFC, size int) {
 	for _, id := range rfcs {
-		count, leader := countGraph(id.Number, processed)
+		if s.isIgnored(id.Number) {
+			continue
+		}
+		count, leader := s.countGraph(id.Number, s.processed)
 
 		if count >= size && count > 0 {
-			graphs = append(graphs, Graph{
+			s.graphs = append(s.graphs, Graph{
 				Leader: leader,
 				Size:   count,
 			})
-			collectEdges(leader.Number, processed, edgeMap)
+			s.collectEdges(leader.Number, s.processed, s.edgeMap)
 		}
 	}
 }
 
-func printGraph(timeline bool) {
-	fmt.Printf("digraph rfc {\n")
-
-	var nodes [Obsoleted + 1][BestCurrentPractice + 1][]*RFC
-	var from, to int
-	var ranks = make(map[int][]*RFC)
-
-	for _, rfc := range RFCs {
-		_, ok := processed[rfc.Number]
-		if !ok {
-			continue
-		}
-		year, err := rfc.Year()
-		if err != nil {
-			panic(err.Error())
-		}
-		if from == 0 || year < from {
-			from = year
-		}
-		if to == 0 || year > to {
-			to = year
-		}
-		ranks[year] = append(ranks[year], rfc)
-
-		nodes[rfc.Type][rfc.Status] = append(nodes[rfc.Type][rfc.Status], rfc)
-	}
-
-	if timeline {
-		fmt.Printf("// %d-%d\n", from, to)
-		fmt.Printf("\tnode [shape=plaintext];\n\t%d", from)
-		for i := from + 1; i <= to; i++ {
-			fmt.Printf(" -> %d", i)
-		}
-		fmt.Printf(";\n\t%d -> Legend [style=invis];\n", to)
-	}
-
-	for t, tarr := range nodes {
-		for s, arr := range tarr {
-			fmt.Printf("\tnode [%s %s]\n", Status(s).Node(), Type(t).Node())
-			for _, rfc := range arr {
-				fmt.Printf("\t%s;\n", rfc.Number)
-			}
-		}
-	}
-
-	if timeline {
-		for i := from; i <= to; i++ {
-			arr, ok := ranks[i]
-			if !ok {
-				continue
-			}
-			fmt.Printf("\t{rank=same %d", i)
-			for _, rfc := range arr {
-				fmt.Printf(" %s", rfc.Number)
-			}
-			fmt.Printf("}\n")
-		}
-
-		for status, _ := range statusName {
-			fmt.Printf("\tnode [%s style=solid];\n", status.Node())
-			fmt.Printf("\t%s;\n", status)
-		}
-		fmt.Printf("\t{rank=same Legend")
-		for status, _ := range statusName {
-			fmt.Printf(" %s", status)
-		}
-		fmt.Printf("}\n")
-	}
-
-	var edges []Edge
-	for _, edge := range edgeMap {
-		edges = append(edges, edge)
-	}
-
-	sort.SliceStable(edges, func(i, j int) bool {
-		if edges[i].From < edges[j].From {
-			return true
-		}
-		if edges[i].From > edges[j].From {
-			return false
-		}
-		return edges[i].To < edges[j].To
-	})
-
-	for _, edge := range edges {
-		fmt.Printf("\t%s -> %s%s;\n", edge.From, edge.To, edge.Type.Edge())
+func (s *Session) collectEdges(id string, processed map[string]*RFC, edges map[string]Edge) {
+	if s.isIgnored(id) {
+		return
 	}
-
-	fmt.Printf("}\n")
-}
-
-func collectEdges(id string, processed map[string]*RFC, edges map[string]Edge) {
 	_, ok := processed[id]
 	if ok {
 		return
 	}
-	rfc := GetRFC(id)
+	rfc := s.getRFC(id)
 	processed[id] = rfc
 
 	for r, t := range rfc.Forwards {
@@ -487,7 +450,7 @@ func collectEdges(id string, processed map[string]*RFC, edges map[string]Edge) {
 			Type: t,
 		}
 		edges[edge.ID()] = edge
-		collectEdges(r, processed, edges)
+		s.collectEdges(r, processed, edges)
 	}
 	for r, t := range rfc.Backwards {
 		edge := Edge{
@@ -496,13 +459,13 @@ func collectEdges(id string, processed map[string]*RFC, edges map[string]Edge) {
 			Type: t,
 		}
 		edges[edge.ID()] = edge
-		collectEdges(r, processed, edges)
+		s.collectEdges(r, processed, edges)
 	}
 }
 
-func countGraph(id string, processed map[string]*RFC) (cnt int, leader *RFC) {
+func (s *Session) countGraph(id string, processed map[string]*RFC) (cnt int, leader *RFC) {
 	graph := make(map[string]*RFC)
-	cnt = count(id, graph, processed)
+	cnt = s.count(id, graph, processed)
 	if cnt > 0 {
 		for _, rfc := range graph {
 			if leader == nil || leader.Number > rfc.Number {
@@ -513,7 +476,10 @@ func countGraph(id string, processed map[string]*RFC) (cnt int, leader *RFC) {
 	return
 }
 
-func count(id string, graph, processed map[string]*RFC) int {
+func (s *Session) count(id string, graph, processed map[string]*RFC) int {
+	if s.isIgnored(id) {
+		return 0
+	}
 	_, ok := processed[id]
 	if ok {
 		return 0
@@ -522,15 +488,15 @@ func count(id string, graph, processed map[string]*RFC) int {
 	if ok {
 		return 0
 	}
-	rfc := GetRFC(id)
+	rfc := s.getRFC(id)
 	graph[id] = rfc
 
 	var c = 1
-	for r, _ := range rfc.Forwards {
-		c += count(r, graph, processed)
+	for r := range rfc.Forwards {
+		c += s.count(r, graph, processed)
 	}
-	for r, _ := range rfc.Backwards {
-		c += count(r, graph, processed)
+	for r := range rfc.Backwards {
+		c += s.count(r, graph, processed)
 	}
 	return c
 }
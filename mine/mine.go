@@ -0,0 +1,346 @@
+//
+// mine.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+// Package mine implements frequent subgraph mining over a directed,
+// vertex- and edge-labeled graph. It follows a right-most extension
+// enumeration: candidate patterns of size n+1 are grown from
+// frequent patterns of size n by extending an embedding with one
+// edge incident to an existing vertex, mirroring the shape of the
+// RFC/Edge model in package main without depending on it.
+package mine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// VertexLabel is the (Status, Type) pair carried by an RFC vertex.
+type VertexLabel struct {
+	Status string
+	Type   string
+}
+
+func (l VertexLabel) String() string {
+	return fmt.Sprintf("%s/%s", l.Status, l.Type)
+}
+
+// Vertex is a labeled RFC node, identified by its RFC number.
+type Vertex struct {
+	ID    int
+	Label VertexLabel
+}
+
+// Edge is a labeled, directed relationship between two RFCs (e.g.
+// "Obsoletes" or "Updates").
+type Edge struct {
+	From, To int
+	Label    string
+}
+
+// Graph is the input to Mine: the full RFC relationship graph.
+type Graph struct {
+	Vertices map[int]Vertex
+	Edges    []Edge
+
+	out map[int][]Edge
+	in  map[int][]Edge
+}
+
+// NewGraph builds a Graph from its vertices and edges, indexing
+// edges by endpoint for traversal during mining.
+func NewGraph(vertices map[int]Vertex, edges []Edge) *Graph {
+	g := &Graph{
+		Vertices: vertices,
+		Edges:    edges,
+		out:      make(map[int][]Edge),
+		in:       make(map[int][]Edge),
+	}
+	for _, e := range edges {
+		g.out[e.From] = append(g.out[e.From], e)
+		g.in[e.To] = append(g.in[e.To], e)
+	}
+	return g
+}
+
+// PatternEdge is an edge of a Pattern, referencing the pattern's own
+// vertex indices rather than RFC numbers.
+type PatternEdge struct {
+	From, To int
+	Label    string
+}
+
+// Pattern is a labeled subgraph shape: a sequence of vertex labels
+// (the pattern's "roles") and the edges between them.
+type Pattern struct {
+	Vertices []VertexLabel
+	Edges    []PatternEdge
+}
+
+// key returns a deterministic signature for p, used to merge
+// extensions that reach the same pattern. The signature follows the
+// order of p's roles, so the same shape reached with its roles in a
+// different order gets a different key and is kept as a separate
+// pattern.
+func (p Pattern) key() string {
+	s := ""
+	for _, v := range p.Vertices {
+		s += v.String() + ";"
+	}
+	s += "|"
+	for _, e := range p.Edges {
+		s += fmt.Sprintf("%d-%s->%d;", e.From, e.Label, e.To)
+	}
+	return s
+}
+
+// Embedding is one occurrence of a Pattern in the graph: the RFC
+// number occupying each pattern role, in Pattern.Vertices order.
+type Embedding struct {
+	Vertices []int
+}
+
+// tupleKey returns the embedding's RFC numbers in role order, used to
+// skip exact duplicate embeddings. Embeddings that visit the same
+// RFCs in a different role order get different keys.
+func (e Embedding) tupleKey() string {
+	s := ""
+	for _, v := range e.Vertices {
+		s += fmt.Sprintf("%d,", v)
+	}
+	return s
+}
+
+func (e Embedding) contains(id int) bool {
+	for _, v := range e.Vertices {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Extension describes how a pattern may grow by one edge: from the
+// existing role at FromRole, via an edge labeled EdgeLabel, to a new
+// vertex labeled ToLabel (or from the new vertex to FromRole, when
+// Forward is false).
+type Extension struct {
+	FromRole  int
+	Forward   bool
+	EdgeLabel string
+	ToLabel   VertexLabel
+}
+
+// Result is one mined pattern: its shape, its support (the minimum
+// number of distinct RFCs occupying any one role, i.e. MNI support),
+// and one example embedding.
+type Result struct {
+	Pattern Pattern
+	Support int
+	Example Embedding
+}
+
+type candidate struct {
+	pattern    Pattern
+	embeddings []Embedding
+	seen       map[string]bool
+
+	// roleOccupancy[i] tracks which RFC numbers have occupied
+	// pattern role i across all recorded embeddings. A single RFC
+	// that fills a role via several embeddings (e.g. a hub RFC
+	// obsoleted by many others) must not inflate support, so support
+	// is the minimum occupancy count over all roles (MNI support)
+	// rather than a raw count of embeddings.
+	roleOccupancy []map[int]bool
+}
+
+func newCandidate(pattern Pattern) *candidate {
+	c := &candidate{
+		pattern:       pattern,
+		seen:          make(map[string]bool),
+		roleOccupancy: make([]map[int]bool, len(pattern.Vertices)),
+	}
+	for i := range c.roleOccupancy {
+		c.roleOccupancy[i] = make(map[int]bool)
+	}
+	return c
+}
+
+// support returns the candidate's MNI support: the fewest distinct
+// RFC numbers occupying any single pattern role.
+func (c *candidate) support() int {
+	support := -1
+	for _, occ := range c.roleOccupancy {
+		if support == -1 || len(occ) < support {
+			support = len(occ)
+		}
+	}
+	if support == -1 {
+		return 0
+	}
+	return support
+}
+
+// Mine discovers the frequent labeled subgraph patterns of size up
+// to maxSize vertices in g whose support is at least minSupport,
+// returned ranked by support, most frequent first.
+func Mine(g *Graph, maxSize, minSupport int) []Result {
+	if maxSize < 2 {
+		return nil
+	}
+
+	current := seed(g)
+	var results []Result
+
+	for size := 2; size <= maxSize; size++ {
+		var frequent []*candidate
+		for _, c := range current {
+			support := c.support()
+			if support < minSupport {
+				continue
+			}
+			frequent = append(frequent, c)
+			results = append(results, Result{
+				Pattern: c.pattern,
+				Support: support,
+				Example: c.embeddings[0],
+			})
+		}
+		if size == maxSize {
+			break
+		}
+		current = grow(g, frequent)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Support != results[j].Support {
+			return results[i].Support > results[j].Support
+		}
+		return len(results[i].Pattern.Vertices) > len(results[j].Pattern.Vertices)
+	})
+	return results
+}
+
+// seed builds the initial size-2 candidates, one per distinct
+// labeled edge (endpoint labels plus edge label).
+func seed(g *Graph) map[string]*candidate {
+	candidates := make(map[string]*candidate)
+
+	for _, e := range g.Edges {
+		from, ok := g.Vertices[e.From]
+		if !ok {
+			continue
+		}
+		to, ok := g.Vertices[e.To]
+		if !ok {
+			continue
+		}
+		pattern := Pattern{
+			Vertices: []VertexLabel{from.Label, to.Label},
+			Edges:    []PatternEdge{{From: 0, To: 1, Label: e.Label}},
+		}
+		key := pattern.key()
+		c, ok := candidates[key]
+		if !ok {
+			c = newCandidate(pattern)
+			candidates[key] = c
+		}
+		emb := Embedding{Vertices: []int{e.From, e.To}}
+		addEmbedding(c, emb)
+	}
+	return candidates
+}
+
+// grow extends every frequent candidate by one edge incident to an
+// existing embedding vertex, using right-most extension: each
+// (role, incident edge) pair becomes an Extension, and new vertices
+// must not already appear in the embedding (overlap pruning).
+func grow(g *Graph, frequent []*candidate) map[string]*candidate {
+	next := make(map[string]*candidate)
+
+	for _, c := range frequent {
+		for _, emb := range c.embeddings {
+			for role, rfc := range emb.Vertices {
+				for _, e := range g.out[rfc] {
+					to, ok := g.Vertices[e.To]
+					if !ok {
+						continue
+					}
+					ext := Extension{
+						FromRole:  role,
+						Forward:   true,
+						EdgeLabel: e.Label,
+						ToLabel:   to.Label,
+					}
+					applyExtension(next, c.pattern, emb, ext, e.To)
+				}
+				for _, e := range g.in[rfc] {
+					from, ok := g.Vertices[e.From]
+					if !ok {
+						continue
+					}
+					ext := Extension{
+						FromRole:  role,
+						Forward:   false,
+						EdgeLabel: e.Label,
+						ToLabel:   from.Label,
+					}
+					applyExtension(next, c.pattern, emb, ext, e.From)
+				}
+			}
+		}
+	}
+	return next
+}
+
+// applyExtension grows pattern/emb by ext, provided newRFC does not
+// already occupy a role in emb, recording the resulting embedding
+// under its extended pattern in next.
+func applyExtension(next map[string]*candidate, pattern Pattern, emb Embedding,
+	ext Extension, newRFC int) {
+
+	if emb.contains(newRFC) {
+		return
+	}
+
+	extended := Pattern{
+		Vertices: append(append([]VertexLabel{}, pattern.Vertices...), ext.ToLabel),
+	}
+	extended.Edges = append([]PatternEdge{}, pattern.Edges...)
+	newRole := len(extended.Vertices) - 1
+	if ext.Forward {
+		extended.Edges = append(extended.Edges,
+			PatternEdge{From: ext.FromRole, To: newRole, Label: ext.EdgeLabel})
+	} else {
+		extended.Edges = append(extended.Edges,
+			PatternEdge{From: newRole, To: ext.FromRole, Label: ext.EdgeLabel})
+	}
+
+	key := extended.key()
+	c, ok := next[key]
+	if !ok {
+		c = newCandidate(extended)
+		next[key] = c
+	}
+	newEmb := Embedding{Vertices: append(append([]int{}, emb.Vertices...), newRFC)}
+	addEmbedding(c, newEmb)
+}
+
+// addEmbedding records emb on c, skipping exact duplicate tuples and
+// updating c.roleOccupancy so c.support() reflects MNI support
+// rather than a raw embedding count.
+func addEmbedding(c *candidate, emb Embedding) {
+	key := emb.tupleKey()
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	for role, rfc := range emb.Vertices {
+		c.roleOccupancy[role][rfc] = true
+	}
+	c.embeddings = append(c.embeddings, emb)
+}
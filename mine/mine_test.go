@@ -0,0 +1,117 @@
+//
+// mine_test.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package mine
+
+import "testing"
+
+// Builds a small graph with two identical "obsoleted by, then
+// updates" chains (100->200->300 and 101->201->301) plus one
+// unrelated edge, so the 2-edge chain pattern has support 2.
+func testGraph() *Graph {
+	label := func(status string) VertexLabel {
+		return VertexLabel{Status: status, Type: "RFC"}
+	}
+	vertices := map[int]Vertex{
+		100: {ID: 100, Label: label("ProposedStandard")},
+		200: {ID: 200, Label: label("InternetStandard")},
+		300: {ID: 300, Label: label("BestCurrentPractice")},
+		101: {ID: 101, Label: label("ProposedStandard")},
+		201: {ID: 201, Label: label("InternetStandard")},
+		301: {ID: 301, Label: label("BestCurrentPractice")},
+		900: {ID: 900, Label: label("Historic")},
+		901: {ID: 901, Label: label("Historic")},
+	}
+	edges := []Edge{
+		{From: 200, To: 100, Label: "Obsoletes"},
+		{From: 200, To: 300, Label: "Updates"},
+		{From: 201, To: 101, Label: "Obsoletes"},
+		{From: 201, To: 301, Label: "Updates"},
+		{From: 900, To: 901, Label: "Obsoletes"},
+	}
+	return NewGraph(vertices, edges)
+}
+
+func TestMineSingleEdgeSupport(t *testing.T) {
+	results := Mine(testGraph(), 2, 2)
+
+	found := false
+	for _, r := range results {
+		if len(r.Pattern.Vertices) == 2 && r.Pattern.Edges[0].Label == "Obsoletes" &&
+			r.Pattern.Vertices[0].Status == "InternetStandard" &&
+			r.Pattern.Vertices[1].Status == "ProposedStandard" {
+			found = true
+			if r.Support != 2 {
+				t.Errorf("got support %d for InternetStandard-Obsoletes-ProposedStandard, want 2", r.Support)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected an Obsoletes pattern in results: %+v", results)
+	}
+}
+
+func TestMineGrowsToThreeVertexChain(t *testing.T) {
+	results := Mine(testGraph(), 3, 2)
+
+	found := false
+	for _, r := range results {
+		if len(r.Pattern.Vertices) == 3 {
+			found = true
+			if r.Support != 2 {
+				t.Errorf("3-vertex pattern support = %d, want 2", r.Support)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a 3-vertex pattern in results: %+v", results)
+	}
+}
+
+func TestMineRespectsMinSupport(t *testing.T) {
+	results := Mine(testGraph(), 3, 10)
+	if len(results) != 0 {
+		t.Fatalf("expected no patterns at min-support 10, got %d", len(results))
+	}
+}
+
+// A single hub RFC obsoleting three different RFCs is only one
+// occurrence of the "InternetStandard-Obsoletes-ProposedStandard"
+// shape, not three: the hub always occupies the "from" role, so MNI
+// support must stay at 1 regardless of how many embeddings share it.
+func TestMineHubDoesNotOverCountSupport(t *testing.T) {
+	label := func(status string) VertexLabel {
+		return VertexLabel{Status: status, Type: "RFC"}
+	}
+	vertices := map[int]Vertex{
+		500: {ID: 500, Label: label("InternetStandard")},
+		501: {ID: 501, Label: label("ProposedStandard")},
+		502: {ID: 502, Label: label("ProposedStandard")},
+		503: {ID: 503, Label: label("ProposedStandard")},
+	}
+	edges := []Edge{
+		{From: 500, To: 501, Label: "Obsoletes"},
+		{From: 500, To: 502, Label: "Obsoletes"},
+		{From: 500, To: 503, Label: "Obsoletes"},
+	}
+	g := NewGraph(vertices, edges)
+
+	results := Mine(g, 2, 1)
+	found := false
+	for _, r := range results {
+		if len(r.Pattern.Vertices) == 2 && r.Pattern.Edges[0].Label == "Obsoletes" {
+			found = true
+			if r.Support != 1 {
+				t.Errorf("got support %d for hub pattern, want 1", r.Support)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected an Obsoletes pattern in results: %+v", results)
+	}
+}
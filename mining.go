@@ -0,0 +1,101 @@
+//
+// mining.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/markkurossi/rfc/mine"
+)
+
+// mineGraph converts the parsed RFC set into the graph shape
+// expected by package mine: vertices labeled by (Status, Type) and
+// edges labeled by relationship type as named by relTypeName
+// ("Obsoleted"/"Updated").
+func mineGraph(rfcs map[string]*RFC) *mine.Graph {
+	vertices := make(map[int]mine.Vertex)
+	var edges []mine.Edge
+
+	for _, rfc := range rfcs {
+		id, err := strconv.Atoi(rfc.Number)
+		if err != nil {
+			continue
+		}
+		vertices[id] = mine.Vertex{
+			ID: id,
+			Label: mine.VertexLabel{
+				Status: rfc.Status.String(),
+				Type:   relTypeName(rfc.Type),
+			},
+		}
+	}
+
+	for _, rfc := range rfcs {
+		from, err := strconv.Atoi(rfc.Number)
+		if err != nil {
+			continue
+		}
+		for ref, t := range rfc.Forwards {
+			to, err := strconv.Atoi(ref)
+			if err != nil {
+				continue
+			}
+			edges = append(edges, mine.Edge{From: from, To: to, Label: relTypeName(t)})
+		}
+		for ref, t := range rfc.Backwards {
+			to, err := strconv.Atoi(ref)
+			if err != nil {
+				continue
+			}
+			edges = append(edges, mine.Edge{From: to, To: from, Label: relTypeName(t)})
+		}
+	}
+
+	return mine.NewGraph(vertices, edges)
+}
+
+// printMinedPatterns runs the frequent subgraph miner and prints the
+// resulting patterns ranked by support, one per line, followed by
+// one example embedding each.
+func printMinedPatterns(w io.Writer, g *mine.Graph, maxSize, minSupport int) {
+	results := mine.Mine(g, maxSize, minSupport)
+
+	for _, r := range results {
+		fmt.Fprintf(w, "support=%d\t%s\n", r.Support, describePattern(r.Pattern))
+		fmt.Fprintf(w, "\texample:")
+		for i, id := range r.Example.Vertices {
+			fmt.Fprintf(w, " %s=%d", roleName(i), id)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func describePattern(p mine.Pattern) string {
+	s := ""
+	for i, v := range p.Vertices {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s(%s)", roleName(i), v.String())
+	}
+	s += ": "
+	for i, e := range p.Edges {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s %s %s", roleName(e.From), e.Label, roleName(e.To))
+	}
+	return s
+}
+
+func roleName(i int) string {
+	return fmt.Sprintf("v%d", i)
+}
@@ -0,0 +1,38 @@
+//
+// mining_test.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import "testing"
+
+// An RFC that records a relationship only in its Backwards map (no
+// mirrored Forwards entry on the other side, as can happen when
+// parsing an isolated index snippet) must still produce an edge.
+func TestMineGraphWalksBackwards(t *testing.T) {
+	rfcs := map[string]*RFC{
+		"100": {
+			Number:   "100",
+			Status:   ProposedStandard,
+			Forwards: map[string]Type{},
+		},
+		"200": {
+			Number:    "200",
+			Status:    InternetStandard,
+			Backwards: map[string]Type{"100": Obsoleted},
+		},
+	}
+
+	g := mineGraph(rfcs)
+	if len(g.Edges) != 1 {
+		t.Fatalf("got %d edges, want 1", len(g.Edges))
+	}
+	edge := g.Edges[0]
+	if edge.From != 100 || edge.To != 200 {
+		t.Errorf("got edge %d->%d, want 100->200", edge.From, edge.To)
+	}
+}
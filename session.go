@@ -0,0 +1,332 @@
+//
+// session.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Session holds the per-command state for the interactive command
+// mode: the set of graphs found so far, the edges collected while
+// walking them, and the focus/ignore/year filters that restrict
+// which RFCs subsequent commands consider.
+type Session struct {
+	all       map[string]*RFC
+	processed map[string]*RFC
+	edgeMap   map[string]Edge
+	graphs    []Graph
+	focusSet  map[string]bool
+	ignored   map[string]bool
+	yearFrom  int
+	yearTo    int
+	output    string
+	last      bytes.Buffer
+}
+
+// NewSession creates a new session over the given RFC set.
+func NewSession(rfcs map[string]*RFC) *Session {
+	return &Session{
+		all:       rfcs,
+		processed: make(map[string]*RFC),
+		edgeMap:   make(map[string]Edge),
+		ignored:   make(map[string]bool),
+		output:    "dot",
+	}
+}
+
+// rfcs returns the RFCs currently in scope for the session, i.e.
+// not excluded by focus/ignore/year.
+func (s *Session) rfcs() []*RFC {
+	var result []*RFC
+	for num, rfc := range s.all {
+		if !s.isIgnored(num) {
+			result = append(result, rfc)
+		}
+	}
+	return result
+}
+
+// getRFC resolves an RFC number against the session's own RFC set,
+// rather than the package-level global, so a Session stays correct
+// even when built over an RFC set other than the one currently
+// installed as the package global.
+func (s *Session) getRFC(id string) *RFC {
+	rfc, ok := s.all[id]
+	if !ok {
+		panic(fmt.Sprintf("Unknown RFC %s", id))
+	}
+	return rfc
+}
+
+// isIgnored tells if the RFC with the given number is outside of
+// the session's current focus/ignore/year scope.
+func (s *Session) isIgnored(id string) bool {
+	if s.ignored[id] {
+		return true
+	}
+	if s.focusSet != nil && !s.focusSet[id] {
+		return true
+	}
+	if s.yearFrom != 0 {
+		rfc, ok := s.all[id]
+		if !ok {
+			return true
+		}
+		year, err := rfc.Year()
+		if err != nil || year < s.yearFrom || year > s.yearTo {
+			return true
+		}
+	}
+	return false
+}
+
+// processedNodes returns the RFCs collected into the session's
+// current set of graphs.
+func (s *Session) processedNodes() map[string]*RFC {
+	nodes := make(map[string]*RFC)
+	for id, rfc := range s.processed {
+		nodes[id] = rfc
+	}
+	return nodes
+}
+
+// edges returns the edges collected into the session's current set
+// of graphs.
+func (s *Session) edges() []Edge {
+	var result []Edge
+	for _, e := range s.edgeMap {
+		result = append(result, e)
+	}
+	return result
+}
+
+// reset clears the accumulated graph state so the next command
+// starts from a clean slate within the current scope.
+func (s *Session) reset() {
+	s.processed = make(map[string]*RFC)
+	s.edgeMap = make(map[string]Edge)
+	s.graphs = nil
+}
+
+// focus restricts the session to the subtree reachable from root,
+// following Forwards and Backwards references.
+func (s *Session) focus(root string) error {
+	rfc, ok := s.all[root]
+	if !ok {
+		return fmt.Errorf("unknown RFC %s", root)
+	}
+	set := make(map[string]bool)
+	var walk func(id string)
+	walk = func(id string) {
+		if set[id] {
+			return
+		}
+		r, ok := s.all[id]
+		if !ok {
+			return
+		}
+		set[id] = true
+		for ref := range r.Forwards {
+			walk(ref)
+		}
+		for ref := range r.Backwards {
+			walk(ref)
+		}
+	}
+	walk(rfc.Number)
+	s.focusSet = set
+	s.reset()
+	return nil
+}
+
+// Run starts the interactive command loop, reading commands from r
+// and writing output and the "rfc> " prompt to w.
+func (s *Session) Run(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	fmt.Fprint(w, "rfc> ")
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 {
+			s.dispatch(w, line)
+		}
+		fmt.Fprint(w, "rfc> ")
+	}
+	fmt.Fprintln(w)
+}
+
+func (s *Session) dispatch(w io.Writer, line string) {
+	fields := strings.Fields(line)
+	cmd := fields[0]
+	args := fields[1:]
+
+	switch cmd {
+	case "traverse":
+		if len(args) != 1 {
+			fmt.Fprintln(w, "usage: traverse <rfc>")
+			return
+		}
+		out := io.MultiWriter(w, s.resetLast())
+		s.printTree(out, args[0])
+
+	case "graph":
+		var rfcs []*RFC
+		switch len(args) {
+		case 0:
+			rfcs = s.rfcs()
+		case 1:
+			rfc, ok := s.all[args[0]]
+			if !ok {
+				fmt.Fprintf(w, "graph: unknown RFC %s\n", args[0])
+				return
+			}
+			if s.isIgnored(args[0]) {
+				fmt.Fprintf(w, "graph: %s is out of scope\n", args[0])
+				return
+			}
+			rfcs = []*RFC{rfc}
+		default:
+			fmt.Fprintln(w, "usage: graph [rfc]")
+			return
+		}
+		s.reset()
+		s.findGraphs(rfcs, 0)
+		out := io.MultiWriter(w, s.resetLast())
+		if s.output == "text" {
+			for _, rfc := range sortedNodes(s.processedNodes()) {
+				fmt.Fprintf(out, "%s\n", rfc)
+			}
+			return
+		}
+		format := s.output
+		if format == "json" {
+			format = "cytoscape"
+		}
+		writer, err := NewGraphWriter(format, false)
+		if err != nil {
+			fmt.Fprintf(w, "graph: %s\n", err)
+			return
+		}
+		if err := writer.Write(out, s.processedNodes(), s.edges()); err != nil {
+			fmt.Fprintf(w, "graph: %s\n", err)
+		}
+
+	case "list":
+		s.listCmd(w, args)
+
+	case "focus":
+		if len(args) != 1 {
+			fmt.Fprintln(w, "usage: focus <rfc>")
+			return
+		}
+		if err := s.focus(args[0]); err != nil {
+			fmt.Fprintf(w, "focus: %s\n", err)
+			return
+		}
+		fmt.Fprintf(w, "focused on %s (%d RFCs in scope)\n",
+			args[0], len(s.focusSet))
+
+	case "ignore":
+		for _, a := range args {
+			s.ignored[a] = true
+		}
+		s.reset()
+
+	case "year":
+		if len(args) != 1 {
+			fmt.Fprintln(w, "usage: year <from>-<to>")
+			return
+		}
+		if err := s.setYear(args[0]); err != nil {
+			fmt.Fprintf(w, "year: %s\n", err)
+			return
+		}
+		s.reset()
+
+	case "set":
+		if len(args) != 2 || args[0] != "output" {
+			fmt.Fprintln(w, "usage: set output dot|json|text")
+			return
+		}
+		s.output = args[1]
+
+	case "write":
+		if len(args) != 1 {
+			fmt.Fprintln(w, "usage: write <file>")
+			return
+		}
+		if err := s.writeLast(args[0]); err != nil {
+			fmt.Fprintf(w, "write: %s\n", err)
+		}
+
+	case "quit", "exit":
+		os.Exit(0)
+
+	default:
+		fmt.Fprintf(w, "unknown command: %s\n", cmd)
+	}
+}
+
+func (s *Session) resetLast() *bytes.Buffer {
+	s.last.Reset()
+	return &s.last
+}
+
+func (s *Session) listCmd(w io.Writer, args []string) {
+	var status string
+	for _, a := range args {
+		if strings.HasPrefix(a, "status=") {
+			status = strings.TrimPrefix(a, "status=")
+		}
+	}
+
+	rfcs := s.rfcs()
+	s.reset()
+	s.findGraphs(rfcs, 0)
+
+	sort.SliceStable(s.graphs, func(i, j int) bool {
+		return s.graphs[i].Leader.Number < s.graphs[j].Leader.Number
+	})
+
+	out := io.MultiWriter(w, s.resetLast())
+	for _, g := range s.graphs {
+		if len(status) > 0 && g.Leader.Status.String() != status {
+			continue
+		}
+		fmt.Fprintf(out, "%s\t%d\t%s\n", g.Leader.Number, g.Size, g.Leader.Title)
+	}
+}
+
+func (s *Session) setYear(arg string) error {
+	parts := strings.SplitN(arg, "-", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid range %q, want <from>-<to>", arg)
+	}
+	from, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return err
+	}
+	to, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+	s.yearFrom = from
+	s.yearTo = to
+	return nil
+}
+
+func (s *Session) writeLast(file string) error {
+	return os.WriteFile(file, s.last.Bytes(), 0644)
+}
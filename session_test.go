@@ -0,0 +1,191 @@
+//
+// session_test.go
+//
+// Copyright (c) 2019 Markku Rossi
+//
+// All rights reserved.
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// testSessionRFCs builds a small, mirrored graph: 200 obsoletes
+// 100, 300 updates 200, and 400 is an unrelated, standalone RFC.
+func testSessionRFCs() map[string]*RFC {
+	rfc100 := &RFC{
+		Number:    "100",
+		Title:     "Old Protocol",
+		Date:      "January 2000",
+		Status:    ProposedStandard,
+		Forwards:  map[string]Type{"200": Obsoleted},
+		Backwards: map[string]Type{},
+	}
+	rfc200 := &RFC{
+		Number:    "200",
+		Title:     "New Protocol",
+		Date:      "January 2010",
+		Status:    InternetStandard,
+		Forwards:  map[string]Type{"300": Updated},
+		Backwards: map[string]Type{"100": Obsoleted},
+	}
+	rfc300 := &RFC{
+		Number:    "300",
+		Title:     "Best Practice",
+		Date:      "January 2015",
+		Status:    BestCurrentPractice,
+		Forwards:  map[string]Type{},
+		Backwards: map[string]Type{"200": Updated},
+	}
+	rfc400 := &RFC{
+		Number:    "400",
+		Title:     "Unrelated",
+		Date:      "January 1999",
+		Status:    Historic,
+		Forwards:  map[string]Type{},
+		Backwards: map[string]Type{},
+	}
+	return map[string]*RFC{
+		"100": rfc100,
+		"200": rfc200,
+		"300": rfc300,
+		"400": rfc400,
+	}
+}
+
+func TestSessionIgnoreAndYearCompose(t *testing.T) {
+	s := NewSession(testSessionRFCs())
+
+	// 100 is explicitly ignored; 400 (year 1999) falls outside the
+	// year range. Both filters must apply even though only one of
+	// them targets each RFC.
+	s.ignored["100"] = true
+	if err := s.setYear("2005-2020"); err != nil {
+		t.Fatalf("setYear: %v", err)
+	}
+
+	if !s.isIgnored("100") {
+		t.Error("100 should be ignored (explicit ignore)")
+	}
+	if !s.isIgnored("400") {
+		t.Error("400 should be excluded by the year filter")
+	}
+	if s.isIgnored("200") {
+		t.Error("200 should be in scope (not ignored, within year range)")
+	}
+	if s.isIgnored("300") {
+		t.Error("300 should be in scope (not ignored, within year range)")
+	}
+}
+
+func TestSessionYearExcludesOutOfRange(t *testing.T) {
+	s := NewSession(testSessionRFCs())
+
+	if err := s.setYear("2005-2012"); err != nil {
+		t.Fatalf("setYear: %v", err)
+	}
+
+	if s.isIgnored("200") {
+		t.Error("200 (year 2010) should be in the 2005-2012 range")
+	}
+	if !s.isIgnored("100") {
+		t.Error("100 (year 2000) should be excluded by the 2005-2012 range")
+	}
+	if !s.isIgnored("300") {
+		t.Error("300 (year 2015) should be excluded by the 2005-2012 range")
+	}
+}
+
+func TestSessionFocusNarrowsScope(t *testing.T) {
+	s := NewSession(testSessionRFCs())
+
+	if err := s.focus("200"); err != nil {
+		t.Fatalf("focus: %v", err)
+	}
+
+	if s.isIgnored("100") {
+		t.Error("100 is reachable from 200 via Backwards, should be in focus")
+	}
+	if s.isIgnored("200") {
+		t.Error("200 is the focus root, should be in scope")
+	}
+	if s.isIgnored("300") {
+		t.Error("300 is reachable from 200 via Forwards (200 is in 300's Backwards), should be in focus")
+	}
+	if !s.isIgnored("400") {
+		t.Error("400 is unrelated to 200's subtree, should be out of focus")
+	}
+}
+
+func TestSessionFocusThenListFiltersByStatus(t *testing.T) {
+	s := NewSession(testSessionRFCs())
+
+	if err := s.focus("200"); err != nil {
+		t.Fatalf("focus: %v", err)
+	}
+
+	var buf bytes.Buffer
+	s.listCmd(&buf, []string{"status=ProposedStandard"})
+
+	out := buf.String()
+	if !strings.Contains(out, "100\t") {
+		t.Errorf("expected 100 (ProposedStandard) in focused+filtered list, got: %q", out)
+	}
+	if strings.Contains(out, "200\t") || strings.Contains(out, "300\t") || strings.Contains(out, "400\t") {
+		t.Errorf("expected only 100 in focused+filtered list, got: %q", out)
+	}
+}
+
+// The "graph <rfc>" command must read its argument: graphing a
+// specific RFC should restrict output to that RFC's connected
+// component, not silently fall back to graphing everything in
+// scope, and an unknown RFC must be rejected rather than ignored.
+func TestSessionGraphCommandReadsArgument(t *testing.T) {
+	s := NewSession(testSessionRFCs())
+	s.output = "text"
+
+	var all bytes.Buffer
+	s.dispatch(&all, "graph")
+
+	var single bytes.Buffer
+	s.dispatch(&single, "graph 400")
+
+	if all.String() == single.String() {
+		t.Fatalf("graph <rfc> produced the same output as graph with no argument: %q", all.String())
+	}
+	if !strings.Contains(single.String(), "400") {
+		t.Errorf("graph 400 should include RFC 400, got: %q", single.String())
+	}
+	if strings.Contains(single.String(), "100") || strings.Contains(single.String(), "200") {
+		t.Errorf("graph 400 should not include unrelated RFCs, got: %q", single.String())
+	}
+
+	var bad bytes.Buffer
+	s.dispatch(&bad, "graph garbage-arg-does-not-exist")
+	if !strings.Contains(bad.String(), "unknown RFC") {
+		t.Errorf("graph with an unknown RFC should report an error, got: %q", bad.String())
+	}
+}
+
+// A Session must resolve RFCs through its own set, not whatever
+// happens to be installed in the package-level RFCs global, so two
+// sessions built over different RFC sets don't interfere.
+func TestSessionIsolatedFromPackageGlobal(t *testing.T) {
+	saved := RFCs
+	RFCs = nil
+	defer func() { RFCs = saved }()
+
+	s := NewSession(testSessionRFCs())
+
+	var buf bytes.Buffer
+	s.listCmd(&buf, nil)
+
+	out := buf.String()
+	if !strings.Contains(out, "100\t") {
+		t.Errorf("expected 100 in list output despite an empty package global, got: %q", out)
+	}
+}